@@ -0,0 +1,104 @@
+// Command server runs whosecourt as a standalone HTTP daemon instead of
+// an AWS Lambda, for self-hosting on Kubernetes or bare metal.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PeladoCollado/reviewers_court/internal/handler"
+	"github.com/PeladoCollado/reviewers_court/labelcache"
+	"go.uber.org/zap"
+)
+
+func main() {
+	listenAddr := os.Getenv("LISTEN_ADDR")
+	if listenAddr == "" {
+		listenAddr = ":8080"
+	}
+	addr := flag.String("listen", listenAddr, "address to listen on")
+	cacheSize := flag.Int("cache-size", 128, "number of repositories' worth of labels to keep in the label cache")
+	warm := flag.String("warm", "", "comma-separated owner/repo list to pre-populate the label cache for, then exit")
+	flag.Parse()
+
+	z, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to initialize logger- %v", err)
+		os.Exit(1)
+	}
+	log := z.Sugar()
+
+	cfg, err := handler.LoadConfig(log)
+	if err != nil {
+		log.Fatalf("Unable to load configuration- %v", err)
+	}
+	cfg.LabelCache, err = labelcache.NewMemoryCache(*cacheSize)
+	if err != nil {
+		log.Fatalf("Unable to initialize label cache- %v", err)
+	}
+
+	h, err := handler.New(cfg)
+	if err != nil {
+		log.Fatalf("Unable to initialize handler- %v", err)
+	}
+
+	if *warm != "" {
+		warmCache(context.Background(), log, h, *warm)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.Handle("/", h)
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: mux,
+	}
+
+	go func() {
+		log.Infof("Listening on %s", *addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Server error- %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Info("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Errorf("Error during shutdown- %v", err)
+	}
+}
+
+// warmCache pre-populates the label cache for a "owner/repo,owner/repo"
+// list of GitHub repositories, so the first webhook delivery after a
+// deploy doesn't pay for a label lookup or creation.
+func warmCache(ctx context.Context, log *zap.SugaredLogger, h *handler.Handler, repos string) {
+	for _, spec := range strings.Split(repos, ",") {
+		spec = strings.TrimSpace(spec)
+		parts := strings.SplitN(spec, "/", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Invalid --warm repo %q, want owner/repo", spec)
+		}
+		if err := h.WarmGitHubRepo(ctx, parts[0], parts[1]); err != nil {
+			log.Errorf("Unable to warm %s- %v", spec, err)
+			continue
+		}
+		log.Infof("Warmed label cache for %s", spec)
+	}
+}
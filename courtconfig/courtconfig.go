@@ -0,0 +1,127 @@
+// Package courtconfig defines whosecourt's per-repository court workflow,
+// loaded from an optional .whosecourt.yml file at the repository root. A
+// repo with no such file gets Default(): the original two-state toggle
+// between reviewers_court and authors_court, with no restriction on which
+// comment-triggered label a PR can be moved to.
+package courtconfig
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigPath is where whosecourt looks for a repository's workflow
+// configuration, relative to the repository root.
+const ConfigPath = ".whosecourt.yml"
+
+// Court is one label in the workflow: its name, and the color whosecourt
+// creates it with if it doesn't already exist.
+type Court struct {
+	Name  string `yaml:"name"`
+	Color string `yaml:"color"`
+}
+
+// Config is a repository's court workflow. A nil map field means "behave
+// as if no config file exists" for that piece of the workflow- see
+// Default.
+type Config struct {
+	// ReviewerCourt and AuthorCourt name the two courts the built-in
+	// review-requested/unlabeled handling moves a PR between.
+	ReviewerCourt string `yaml:"reviewer_court"`
+	AuthorCourt   string `yaml:"author_court"`
+
+	// Courts lists every label this workflow can move a PR into,
+	// including ReviewerCourt and AuthorCourt. A nil Courts means just
+	// those two, created with gitsource's default color.
+	Courts []Court `yaml:"courts"`
+
+	// Transitions maps a normalized webhook action (see
+	// gitsource.EventAction) to the court a PR moves to when it fires.
+	// This is how additional courts like ci_court or qa_court get
+	// reached. A nil Transitions means every review_requested/reviewed
+	// event moves the PR to ReviewerCourt- the original behavior. The
+	// handler only looks up the review_requested and reviewed actions
+	// here; unlabeled events always move between AuthorCourt and
+	// ReviewerCourt, and comment events go through Comments.
+	Transitions map[string]string `yaml:"transitions"`
+
+	// Comments maps a "<!-- name -->" PR comment to the court it moves a
+	// PR to. A nil Comments means any "<!-- name -->" comment moves the
+	// PR straight to the label "name", same as before courts were
+	// configurable.
+	Comments map[string]string `yaml:"comments"`
+}
+
+// Default is the workflow whosecourt has always run.
+func Default() *Config {
+	return &Config{
+		ReviewerCourt: "reviewers_court",
+		AuthorCourt:   "authors_court",
+	}
+}
+
+// Parse reads a .whosecourt.yml file, filling in ReviewerCourt/AuthorCourt
+// from Default() when the file leaves them blank.
+func Parse(data []byte) (*Config, error) {
+	cfg := Default()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrap(err, "invalid "+ConfigPath)
+	}
+	if cfg.ReviewerCourt == "" {
+		cfg.ReviewerCourt = "reviewers_court"
+	}
+	if cfg.AuthorCourt == "" {
+		cfg.AuthorCourt = "authors_court"
+	}
+	return cfg, nil
+}
+
+// CourtNames returns every label this workflow should ensure exists,
+// falling back to just ReviewerCourt/AuthorCourt when Courts isn't set.
+func (c *Config) CourtNames() []string {
+	if len(c.Courts) == 0 {
+		return []string{c.ReviewerCourt, c.AuthorCourt}
+	}
+	names := make([]string, len(c.Courts))
+	for i, court := range c.Courts {
+		names[i] = court.Name
+	}
+	return names
+}
+
+// ColorFor returns the configured color for a court name, or "" if Courts
+// doesn't mention it- in which case the gitsource implementation picks
+// its own default.
+func (c *Config) ColorFor(name string) string {
+	for _, court := range c.Courts {
+		if court.Name == name {
+			return court.Color
+		}
+	}
+	return ""
+}
+
+// CourtForAction returns the court a PR should move to when action fires,
+// consulting Transitions first and falling back to ReviewerCourt- the
+// original, un-configurable behavior for review_requested and reviewed
+// events.
+func (c *Config) CourtForAction(action string) string {
+	if c.Transitions != nil {
+		if court, ok := c.Transitions[action]; ok {
+			return court
+		}
+	}
+	return c.ReviewerCourt
+}
+
+// CourtForComment returns the court a "<!-- name -->" PR comment should
+// move a PR to, and whether that comment is honored at all. With no
+// Comments map configured, any name is honored and maps to itself- the
+// original, unrestricted behavior.
+func (c *Config) CourtForComment(name string) (string, bool) {
+	if c.Comments == nil {
+		return name, true
+	}
+	court, ok := c.Comments[name]
+	return court, ok
+}
@@ -0,0 +1,107 @@
+package courtconfig
+
+import "testing"
+
+func TestParseEmptyFallsBackToDefault(t *testing.T) {
+	cfg, err := Parse(nil)
+	if err != nil {
+		t.Fatalf("Parse(nil) error = %v", err)
+	}
+	if cfg.ReviewerCourt != "reviewers_court" || cfg.AuthorCourt != "authors_court" {
+		t.Errorf("Parse(nil) = %+v, want default court names", cfg)
+	}
+	if len(cfg.CourtNames()) != 2 {
+		t.Errorf("CourtNames() = %v, want the two default courts", cfg.CourtNames())
+	}
+}
+
+func TestParseInvalidYAML(t *testing.T) {
+	if _, err := Parse([]byte("not: [valid: yaml")); err == nil {
+		t.Error("Parse() with malformed YAML returned nil error")
+	}
+}
+
+func TestParseOverridesCourtNames(t *testing.T) {
+	cfg, err := Parse([]byte(`
+reviewer_court: needs_review
+author_court: needs_changes
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.ReviewerCourt != "needs_review" || cfg.AuthorCourt != "needs_changes" {
+		t.Errorf("Parse() = %+v, want overridden court names", cfg)
+	}
+}
+
+func TestParseAdditionalCourts(t *testing.T) {
+	cfg, err := Parse([]byte(`
+courts:
+  - name: reviewers_court
+    color: "ff0000"
+  - name: ci_court
+    color: "00ff00"
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	names := cfg.CourtNames()
+	if len(names) != 2 || names[0] != "reviewers_court" || names[1] != "ci_court" {
+		t.Errorf("CourtNames() = %v, want [reviewers_court ci_court]", names)
+	}
+	if color := cfg.ColorFor("ci_court"); color != "00ff00" {
+		t.Errorf("ColorFor(ci_court) = %q, want 00ff00", color)
+	}
+	if color := cfg.ColorFor("unknown_court"); color != "" {
+		t.Errorf("ColorFor(unknown_court) = %q, want empty string", color)
+	}
+}
+
+func TestCourtForActionFallsBackToReviewerCourt(t *testing.T) {
+	cfg := Default()
+	if got := cfg.CourtForAction("review_requested"); got != cfg.ReviewerCourt {
+		t.Errorf("CourtForAction() = %q, want %q", got, cfg.ReviewerCourt)
+	}
+}
+
+func TestCourtForActionHonorsTransitions(t *testing.T) {
+	cfg, err := Parse([]byte(`
+transitions:
+  ci_passed: qa_court
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := cfg.CourtForAction("ci_passed"); got != "qa_court" {
+		t.Errorf("CourtForAction(ci_passed) = %q, want qa_court", got)
+	}
+	if got := cfg.CourtForAction("review_requested"); got != cfg.ReviewerCourt {
+		t.Errorf("CourtForAction(review_requested) = %q, want %q (unconfigured action falls back)", got, cfg.ReviewerCourt)
+	}
+}
+
+func TestCourtForCommentDefaultsToSelf(t *testing.T) {
+	cfg := Default()
+	court, ok := cfg.CourtForComment("authors_court")
+	if !ok || court != "authors_court" {
+		t.Errorf("CourtForComment() = (%q, %v), want (authors_court, true)", court, ok)
+	}
+}
+
+func TestCourtForCommentHonorsConfiguredMap(t *testing.T) {
+	cfg, err := Parse([]byte(`
+comments:
+  lgtm: authors_court
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if court, ok := cfg.CourtForComment("lgtm"); !ok || court != "authors_court" {
+		t.Errorf("CourtForComment(lgtm) = (%q, %v), want (authors_court, true)", court, ok)
+	}
+	if _, ok := cfg.CourtForComment("not_configured"); ok {
+		t.Error("CourtForComment(not_configured) = ok, want false once Comments is configured")
+	}
+}
@@ -0,0 +1,171 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/pkg/errors"
+)
+
+// GiteaSource talks to a Gitea (or Forgejo) instance through an
+// already-authenticated *gitea.Client.
+type GiteaSource struct {
+	Client *gitea.Client
+}
+
+// NewGiteaSource wraps an authenticated Gitea client.
+func NewGiteaSource(client *gitea.Client) *GiteaSource {
+	return &GiteaSource{Client: client}
+}
+
+func (s *GiteaSource) Name() string { return "gitea" }
+
+// Gitea's SDK (code.gitea.io/sdk/gitea) is a thin REST API client and does
+// not export the webhook payload/action types below -- those live in
+// Gitea's own server-side "models" package, which this app does not
+// import. We decode the fields we actually need by hand, reusing the
+// SDK's API types (gitea.PullRequest, gitea.Issue, gitea.Comment, ...)
+// wherever the webhook JSON shape matches the REST response shape, which
+// for Gitea it does.
+//
+// Action strings match what Gitea sends in the webhook payload's
+// "action" field; see Gitea's webhook_struct.go / notifier_webhook.go.
+const (
+	giteaActionOpened       = "opened"
+	giteaActionReopened     = "reopened"
+	giteaActionAssigned     = "assigned"
+	giteaActionLabelCleared = "label_cleared"
+)
+
+type giteaPullRequestPayload struct {
+	Action      string             `json:"action"`
+	Number      int64              `json:"number"`
+	PullRequest *gitea.PullRequest `json:"pull_request"`
+	Repository  *gitea.Repository  `json:"repository"`
+	Sender      *gitea.User        `json:"sender"`
+}
+
+type giteaIssueCommentPayload struct {
+	Action     string            `json:"action"`
+	Issue      *gitea.Issue      `json:"issue"`
+	Comment    *gitea.Comment    `json:"comment"`
+	Repository *gitea.Repository `json:"repository"`
+	Sender     *gitea.User       `json:"sender"`
+}
+
+func (s *GiteaSource) ParseEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "pull_request":
+		event := &giteaPullRequestPayload{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		pr := pullRequestPayloadToPullRequest(event)
+
+		switch event.Action {
+		case giteaActionAssigned, giteaActionOpened, giteaActionReopened:
+			return &Event{Action: ActionReviewRequested, PullRequest: pr}, nil
+		case giteaActionLabelCleared:
+			if event.Sender != nil {
+				pr.SenderID = event.Sender.ID
+			}
+			return &Event{Action: ActionUnlabeled, PullRequest: pr}, nil
+		}
+		return &Event{Action: ActionIgnored, PullRequest: pr}, nil
+	case "pull_request_review":
+		event := &giteaPullRequestPayload{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		return &Event{Action: ActionReviewed, PullRequest: pullRequestPayloadToPullRequest(event)}, nil
+	case "pull_request_comment", "issue_comment":
+		event := &giteaIssueCommentPayload{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		pr := &PullRequest{
+			Owner:    event.Repository.Owner.UserName,
+			Repo:     event.Repository.Name,
+			Number:   int(event.Issue.Index),
+			AuthorID: event.Issue.Poster.ID,
+		}
+		return &Event{Action: ActionCourtComment, PullRequest: pr, Comment: event.Comment.Body}, nil
+	default:
+		return &Event{Action: ActionIgnored}, nil
+	}
+}
+
+func pullRequestPayloadToPullRequest(event *giteaPullRequestPayload) *PullRequest {
+	pr := &PullRequest{
+		Owner:    event.Repository.Owner.UserName,
+		Repo:     event.Repository.Name,
+		Number:   int(event.PullRequest.Index),
+		AuthorID: event.PullRequest.Poster.ID,
+	}
+	for _, l := range event.PullRequest.Labels {
+		pr.LabelIDs = append(pr.LabelIDs, l.ID)
+	}
+	return pr
+}
+
+func (s *GiteaSource) LoadOrCreateLabel(ctx context.Context, owner, repo, name, color string) (*Label, error) {
+	labels, _, err := s.Client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		if l.Name == name {
+			return &Label{ID: l.ID, Name: l.Name}, nil
+		}
+	}
+	if color == "" {
+		color = DefaultLabelColor
+	}
+	created, _, err := s.Client.CreateLabel(owner, repo, gitea.CreateLabelOption{
+		Name:  name,
+		Color: "#" + strings.TrimPrefix(color, "#"),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("unable to create label %s", name))
+	}
+	return &Label{ID: created.ID, Name: created.Name}, nil
+}
+
+func (s *GiteaSource) AddLabel(ctx context.Context, pr *PullRequest, name string) error {
+	label, err := s.LoadOrCreateLabel(ctx, pr.Owner, pr.Repo, name, "")
+	if err != nil {
+		return err
+	}
+	_, _, err = s.Client.AddIssueLabels(pr.Owner, pr.Repo, int64(pr.Number), gitea.IssueLabelsOption{
+		Labels: []int64{label.ID},
+	})
+	return err
+}
+
+func (s *GiteaSource) RemoveLabel(ctx context.Context, pr *PullRequest, name string) error {
+	label, err := s.LoadOrCreateLabel(ctx, pr.Owner, pr.Repo, name, "")
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.DeleteIssueLabel(pr.Owner, pr.Repo, int64(pr.Number), label.ID)
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return err
+	}
+	return nil
+}
+
+func (s *GiteaSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	pr, _, err := s.Client.GetPullRequest(owner, repo, int64(number))
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{
+		Owner:    owner,
+		Repo:     repo,
+		Number:   int(pr.Index),
+		AuthorID: pr.Poster.ID,
+	}, nil
+}
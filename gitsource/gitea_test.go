@@ -0,0 +1,111 @@
+package gitsource
+
+import "testing"
+
+func TestGiteaParseEventPullRequestOpened(t *testing.T) {
+	s := &GiteaSource{}
+	body := []byte(`{
+		"action": "opened",
+		"number": 5,
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"name": "widgets", "owner": {"login": "acme"}}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewRequested {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionReviewRequested)
+	}
+	if event.PullRequest.Owner != "acme" || event.PullRequest.Repo != "widgets" || event.PullRequest.Number != 5 {
+		t.Errorf("PullRequest = %+v, want owner=acme repo=widgets number=5", event.PullRequest)
+	}
+}
+
+func TestGiteaParseEventLabelCleared(t *testing.T) {
+	s := &GiteaSource{}
+	body := []byte(`{
+		"action": "label_cleared",
+		"sender": {"id": 7},
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"name": "widgets", "owner": {"login": "acme"}}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionUnlabeled {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionUnlabeled)
+	}
+	if event.PullRequest.SenderID != 7 {
+		t.Errorf("SenderID = %d, want 7", event.PullRequest.SenderID)
+	}
+}
+
+func TestGiteaParseEventPullRequestIgnoredAction(t *testing.T) {
+	s := &GiteaSource{}
+	body := []byte(`{
+		"action": "synchronized",
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"name": "widgets", "owner": {"login": "acme"}}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Errorf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+}
+
+func TestGiteaParseEventReview(t *testing.T) {
+	s := &GiteaSource{}
+	body := []byte(`{
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"name": "widgets", "owner": {"login": "acme"}}
+	}`)
+	event, err := s.ParseEvent("pull_request_review", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewed {
+		t.Errorf("Action = %q, want %q", event.Action, ActionReviewed)
+	}
+}
+
+func TestGiteaParseEventIssueComment(t *testing.T) {
+	s := &GiteaSource{}
+	body := []byte(`{
+		"issue": {"number": 5, "user": {"id": 42}},
+		"repository": {"name": "widgets", "owner": {"login": "acme"}},
+		"comment": {"body": "<!-- authors_court -->"}
+	}`)
+	event, err := s.ParseEvent("issue_comment", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionCourtComment {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionCourtComment)
+	}
+	if event.Comment != "<!-- authors_court -->" {
+		t.Errorf("Comment = %q, want the raw comment body", event.Comment)
+	}
+}
+
+func TestGiteaParseEventUnknownType(t *testing.T) {
+	s := &GiteaSource{}
+	event, err := s.ParseEvent("repository", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Errorf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+}
+
+func TestGiteaParseEventInvalidJSON(t *testing.T) {
+	s := &GiteaSource{}
+	if _, err := s.ParseEvent("pull_request", []byte(`not json`)); err == nil {
+		t.Error("ParseEvent() with malformed JSON returned nil error")
+	}
+}
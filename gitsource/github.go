@@ -0,0 +1,163 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// GitHubSource talks to github.com, or a GitHub Enterprise Server instance,
+// through an already-authenticated *github.Client.
+type GitHubSource struct {
+	Client *github.Client
+}
+
+// NewGitHubSource wraps an authenticated GitHub client.
+func NewGitHubSource(client *github.Client) *GitHubSource {
+	return &GitHubSource{Client: client}
+}
+
+func (s *GitHubSource) Name() string { return "github" }
+
+func (s *GitHubSource) ParseEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "ping":
+		return &Event{Action: ActionPing}, nil
+	case "pull_request":
+		event := &github.PullRequestEvent{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		owner, repoName, err := repoOwnerAndName(event.Repo)
+		if err != nil {
+			return nil, err
+		}
+		pr := normalizePullRequest(event.PullRequest, owner, repoName)
+
+		switch event.GetAction() {
+		case "review_requested", "opened", "reopened":
+			if event.RequestedReviewer != nil || len(event.PullRequest.RequestedReviewers) > 0 {
+				return &Event{Action: ActionReviewRequested, PullRequest: pr}, nil
+			}
+		case "unlabeled":
+			pr.SenderID = event.GetSender().GetID()
+			return &Event{Action: ActionUnlabeled, PullRequest: pr}, nil
+		}
+		return &Event{Action: ActionIgnored, PullRequest: pr}, nil
+	case "pull_request_review":
+		event := &github.PullRequestReviewEvent{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		owner, repoName, err := repoOwnerAndName(event.Repo)
+		if err != nil {
+			return nil, err
+		}
+		return &Event{Action: ActionReviewed, PullRequest: normalizePullRequest(event.PullRequest, owner, repoName)}, nil
+	case "pull_request_review_comment":
+		event := &github.PullRequestReviewCommentEvent{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		owner, repoName, err := repoOwnerAndName(event.Repo)
+		if err != nil {
+			return nil, err
+		}
+		return &Event{
+			Action:      ActionCourtComment,
+			PullRequest: normalizePullRequest(event.PullRequest, owner, repoName),
+			Comment:     event.Comment.GetBody(),
+		}, nil
+	default:
+		return &Event{Action: ActionIgnored}, nil
+	}
+}
+
+func (s *GitHubSource) LoadOrCreateLabel(ctx context.Context, owner, repo, name, color string) (*Label, error) {
+	label, resp, err := s.Client.Issues.GetLabel(ctx, owner, repo, name)
+	if resp != nil && resp.StatusCode == 404 {
+		if color == "" {
+			color = DefaultLabelColor
+		}
+		label, _, err = s.Client.Issues.CreateLabel(ctx, owner, repo, &github.Label{Name: &name, Color: &color})
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("unable to create label %s", name))
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return &Label{ID: label.GetID(), Name: label.GetName()}, nil
+}
+
+// GetConfigFile fetches path from owner/repo's default branch, returning
+// ok=false (rather than an error) if the file doesn't exist. Used to load
+// .whosecourt.yml.
+func (s *GitHubSource) GetConfigFile(ctx context.Context, owner, repo, path string) (content []byte, ok bool, err error) {
+	file, _, resp, err := s.Client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	if resp != nil && resp.StatusCode == 404 {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if file == nil {
+		return nil, false, nil
+	}
+	decoded, err := file.GetContent()
+	if err != nil {
+		return nil, false, errors.Wrap(err, fmt.Sprintf("unable to decode %s", path))
+	}
+	return []byte(decoded), true, nil
+}
+
+func (s *GitHubSource) AddLabel(ctx context.Context, pr *PullRequest, name string) error {
+	_, _, err := s.Client.Issues.AddLabelsToIssue(ctx, pr.Owner, pr.Repo, pr.Number, []string{name})
+	return err
+}
+
+func (s *GitHubSource) RemoveLabel(ctx context.Context, pr *PullRequest, name string) error {
+	resp, err := s.Client.Issues.RemoveLabelForIssue(ctx, pr.Owner, pr.Repo, pr.Number, name)
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return err
+	}
+	return nil
+}
+
+func (s *GitHubSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	pr, _, err := s.Client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	return normalizePullRequest(pr, owner, repo), nil
+}
+
+func normalizePullRequest(pr *github.PullRequest, owner, repo string) *PullRequest {
+	normalized := &PullRequest{
+		Owner:    owner,
+		Repo:     repo,
+		Number:   pr.GetNumber(),
+		AuthorID: pr.GetUser().GetID(),
+	}
+	for _, l := range pr.Labels {
+		normalized.LabelIDs = append(normalized.LabelIDs, l.GetID())
+	}
+	return normalized
+}
+
+func repoOwnerAndName(repo *github.Repository) (string, string, error) {
+	if repo == nil {
+		return "", "", fmt.Errorf("event did not include repository information")
+	}
+	if repo.Owner != nil && repo.Owner.Name != nil {
+		return *repo.Owner.Name, repo.GetName(), nil
+	}
+	if strings.Count(repo.GetFullName(), "/") == 1 {
+		parts := strings.Split(repo.GetFullName(), "/")
+		return parts[0], parts[1], nil
+	}
+	return "", "", fmt.Errorf("can't determine repository information from event %v", *repo)
+}
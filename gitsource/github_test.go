@@ -0,0 +1,133 @@
+package gitsource
+
+import "testing"
+
+func TestGitHubParseEventPing(t *testing.T) {
+	s := &GitHubSource{}
+	event, err := s.ParseEvent("ping", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionPing {
+		t.Errorf("Action = %q, want %q", event.Action, ActionPing)
+	}
+}
+
+func TestGitHubParseEventPullRequestReviewRequested(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{
+		"action": "review_requested",
+		"requested_reviewer": {"id": 99},
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"full_name": "acme/widgets"}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewRequested {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionReviewRequested)
+	}
+	if event.PullRequest.Owner != "acme" || event.PullRequest.Repo != "widgets" || event.PullRequest.Number != 5 {
+		t.Errorf("PullRequest = %+v, want owner=acme repo=widgets number=5", event.PullRequest)
+	}
+}
+
+func TestGitHubParseEventUnlabeled(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{
+		"action": "unlabeled",
+		"sender": {"id": 7},
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"full_name": "acme/widgets"}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionUnlabeled {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionUnlabeled)
+	}
+	if event.PullRequest.SenderID != 7 {
+		t.Errorf("SenderID = %d, want 7", event.PullRequest.SenderID)
+	}
+}
+
+func TestGitHubParseEventPullRequestIgnoredAction(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{
+		"action": "synchronize",
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"full_name": "acme/widgets"}
+	}`)
+	event, err := s.ParseEvent("pull_request", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+	if event.PullRequest == nil {
+		t.Error("PullRequest = nil, want it populated even for an ignored action")
+	}
+}
+
+func TestGitHubParseEventReview(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"full_name": "acme/widgets"}
+	}`)
+	event, err := s.ParseEvent("pull_request_review", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewed {
+		t.Errorf("Action = %q, want %q", event.Action, ActionReviewed)
+	}
+}
+
+func TestGitHubParseEventComment(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{
+		"pull_request": {"number": 5, "user": {"id": 42}},
+		"repository": {"full_name": "acme/widgets"},
+		"comment": {"body": "<!-- authors_court -->"}
+	}`)
+	event, err := s.ParseEvent("pull_request_review_comment", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionCourtComment {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionCourtComment)
+	}
+	if event.Comment != "<!-- authors_court -->" {
+		t.Errorf("Comment = %q, want the raw comment body", event.Comment)
+	}
+}
+
+func TestGitHubParseEventUnknownType(t *testing.T) {
+	s := &GitHubSource{}
+	event, err := s.ParseEvent("star", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Errorf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+}
+
+func TestGitHubParseEventInvalidJSON(t *testing.T) {
+	s := &GitHubSource{}
+	if _, err := s.ParseEvent("pull_request", []byte(`not json`)); err == nil {
+		t.Error("ParseEvent() with malformed JSON returned nil error")
+	}
+}
+
+func TestGitHubParseEventMissingRepository(t *testing.T) {
+	s := &GitHubSource{}
+	body := []byte(`{"action": "opened", "pull_request": {"number": 5, "user": {"id": 42}}}`)
+	if _, err := s.ParseEvent("pull_request", body); err == nil {
+		t.Error("ParseEvent() with no repository info returned nil error")
+	}
+}
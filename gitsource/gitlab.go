@@ -0,0 +1,135 @@
+package gitsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	gitlab "github.com/xanzy/go-gitlab"
+)
+
+// GitLabSource talks to gitlab.com, or a self-managed GitLab instance,
+// through an already-authenticated *gitlab.Client.
+type GitLabSource struct {
+	Client *gitlab.Client
+}
+
+// NewGitLabSource wraps an authenticated GitLab client.
+func NewGitLabSource(client *gitlab.Client) *GitLabSource {
+	return &GitLabSource{Client: client}
+}
+
+func (s *GitLabSource) Name() string { return "gitlab" }
+
+func (s *GitLabSource) ParseEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "Merge Request Hook":
+		event := &gitlab.MergeEvent{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		pr := mergeEventToPullRequest(event)
+
+		switch event.ObjectAttributes.Action {
+		case "open", "reopen":
+			return &Event{Action: ActionReviewRequested, PullRequest: pr}, nil
+		case "approved", "unapproved":
+			return &Event{Action: ActionReviewed, PullRequest: pr}, nil
+		}
+		return &Event{Action: ActionIgnored, PullRequest: pr}, nil
+	case "Note Hook":
+		event := &gitlab.MergeCommentEvent{}
+		if err := json.Unmarshal(body, event); err != nil {
+			return nil, errors.Wrap(err, "invalid JSON received")
+		}
+		pr := &PullRequest{
+			Owner:    event.Project.Namespace,
+			Repo:     event.Project.Name,
+			Number:   event.MergeRequest.IID,
+			AuthorID: int64(event.MergeRequest.AuthorID),
+		}
+		return &Event{Action: ActionCourtComment, PullRequest: pr, Comment: event.ObjectAttributes.Note}, nil
+	default:
+		return &Event{Action: ActionIgnored}, nil
+	}
+}
+
+func mergeEventToPullRequest(event *gitlab.MergeEvent) *PullRequest {
+	pr := &PullRequest{
+		Owner:    event.Project.Namespace,
+		Repo:     event.Project.Name,
+		Number:   event.ObjectAttributes.IID,
+		AuthorID: int64(event.ObjectAttributes.AuthorID),
+	}
+	for _, l := range event.Labels {
+		pr.LabelIDs = append(pr.LabelIDs, int64(l.ID))
+	}
+	return pr
+}
+
+func (s *GitLabSource) LoadOrCreateLabel(ctx context.Context, owner, repo, name, color string) (*Label, error) {
+	projectID := fmt.Sprintf("%s/%s", owner, repo)
+	opt := &gitlab.ListLabelsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		labels, resp, err := s.Client.Labels.ListLabels(projectID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range labels {
+			if l.Name == name {
+				return &Label{ID: int64(l.ID), Name: l.Name}, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	if color == "" {
+		color = DefaultLabelColor
+	}
+	created, _, err := s.Client.Labels.CreateLabel(projectID, &gitlab.CreateLabelOptions{
+		Name:  &name,
+		Color: gitlab.String("#" + strings.TrimPrefix(color, "#")),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("unable to create label %s", name))
+	}
+	return &Label{ID: int64(created.ID), Name: created.Name}, nil
+}
+
+func (s *GitLabSource) AddLabel(ctx context.Context, pr *PullRequest, name string) error {
+	projectID := fmt.Sprintf("%s/%s", pr.Owner, pr.Repo)
+	_, _, err := s.Client.MergeRequests.UpdateMergeRequest(projectID, pr.Number, &gitlab.UpdateMergeRequestOptions{
+		AddLabels: &gitlab.Labels{name},
+	})
+	return err
+}
+
+func (s *GitLabSource) RemoveLabel(ctx context.Context, pr *PullRequest, name string) error {
+	projectID := fmt.Sprintf("%s/%s", pr.Owner, pr.Repo)
+	_, resp, err := s.Client.MergeRequests.UpdateMergeRequest(projectID, pr.Number, &gitlab.UpdateMergeRequestOptions{
+		RemoveLabels: &gitlab.Labels{name},
+	})
+	if err != nil && (resp == nil || resp.StatusCode != 404) {
+		return err
+	}
+	return nil
+}
+
+func (s *GitLabSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	projectID := fmt.Sprintf("%s/%s", owner, repo)
+	mr, _, err := s.Client.MergeRequests.GetMergeRequest(projectID, number, &gitlab.GetMergeRequestsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pr := &PullRequest{
+		Owner:    owner,
+		Repo:     repo,
+		Number:   mr.IID,
+		AuthorID: int64(mr.Author.ID),
+	}
+	return pr, nil
+}
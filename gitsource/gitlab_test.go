@@ -0,0 +1,95 @@
+package gitsource
+
+import "testing"
+
+func TestGitLabParseEventMergeRequestOpened(t *testing.T) {
+	s := &GitLabSource{}
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"namespace": "acme", "name": "widgets"},
+		"object_attributes": {"iid": 5, "author_id": 42, "action": "open"}
+	}`)
+	event, err := s.ParseEvent("Merge Request Hook", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewRequested {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionReviewRequested)
+	}
+	if event.PullRequest.Owner != "acme" || event.PullRequest.Repo != "widgets" || event.PullRequest.Number != 5 {
+		t.Errorf("PullRequest = %+v, want owner=acme repo=widgets number=5", event.PullRequest)
+	}
+}
+
+func TestGitLabParseEventMergeRequestApproved(t *testing.T) {
+	s := &GitLabSource{}
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"namespace": "acme", "name": "widgets"},
+		"object_attributes": {"iid": 5, "author_id": 42, "action": "approved"}
+	}`)
+	event, err := s.ParseEvent("Merge Request Hook", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionReviewed {
+		t.Errorf("Action = %q, want %q", event.Action, ActionReviewed)
+	}
+}
+
+func TestGitLabParseEventMergeRequestIgnoredAction(t *testing.T) {
+	s := &GitLabSource{}
+	body := []byte(`{
+		"object_kind": "merge_request",
+		"project": {"namespace": "acme", "name": "widgets"},
+		"object_attributes": {"iid": 5, "author_id": 42, "action": "close"}
+	}`)
+	event, err := s.ParseEvent("Merge Request Hook", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Errorf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+}
+
+func TestGitLabParseEventNote(t *testing.T) {
+	s := &GitLabSource{}
+	body := []byte(`{
+		"object_kind": "note",
+		"project": {"namespace": "acme", "name": "widgets"},
+		"object_attributes": {"note": "<!-- authors_court -->"},
+		"merge_request": {"iid": 5, "author_id": 42}
+	}`)
+	event, err := s.ParseEvent("Note Hook", body)
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionCourtComment {
+		t.Fatalf("Action = %q, want %q", event.Action, ActionCourtComment)
+	}
+	if event.Comment != "<!-- authors_court -->" {
+		t.Errorf("Comment = %q, want the raw note body", event.Comment)
+	}
+	if event.PullRequest.Number != 5 {
+		t.Errorf("PullRequest.Number = %d, want 5", event.PullRequest.Number)
+	}
+}
+
+func TestGitLabParseEventUnknownType(t *testing.T) {
+	s := &GitLabSource{}
+	event, err := s.ParseEvent("Pipeline Hook", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("ParseEvent() error = %v", err)
+	}
+	if event.Action != ActionIgnored {
+		t.Errorf("Action = %q, want %q", event.Action, ActionIgnored)
+	}
+}
+
+func TestGitLabParseEventInvalidJSON(t *testing.T) {
+	s := &GitLabSource{}
+	if _, err := s.ParseEvent("Merge Request Hook", []byte(`not json`)); err == nil {
+		t.Error("ParseEvent() with malformed JSON returned nil error")
+	}
+}
@@ -0,0 +1,76 @@
+// Package gitsource abstracts the forge-specific bits of whosecourt (GitHub,
+// GitLab, Gitea, ...) behind a single interface so the reviewer's-court /
+// author's-court workflow can run against any of them.
+package gitsource
+
+import "context"
+
+// PullRequest is a normalized reference to a pull/merge request, independent
+// of which forge it came from.
+type PullRequest struct {
+	Owner    string
+	Repo     string
+	Number   int
+	AuthorID int64
+	SenderID int64
+	LabelIDs []int64
+}
+
+// Label is a normalized court label.
+type Label struct {
+	ID   int64
+	Name string
+}
+
+// DefaultLabelColor is used when creating a label whose color wasn't
+// configured, e.g. by courtconfig.
+const DefaultLabelColor = "ededed"
+
+// EventAction is the normalized action a webhook event represents.
+type EventAction string
+
+const (
+	ActionReviewRequested EventAction = "review_requested"
+	ActionUnlabeled       EventAction = "unlabeled"
+	ActionReviewed        EventAction = "reviewed"
+	ActionCourtComment    EventAction = "court_comment"
+	ActionPing            EventAction = "ping"
+	ActionIgnored         EventAction = "ignored"
+)
+
+// Event is a webhook payload normalized into the shape whosecourt cares
+// about: what happened, on which pull request, and (for comment events)
+// what was said.
+type Event struct {
+	Action      EventAction
+	PullRequest *PullRequest
+	Comment     string
+}
+
+// Source is implemented once per forge. It wraps an already-authenticated
+// client for that forge, parses its webhook events, and moves labels
+// around on a pull request.
+type Source interface {
+	// Name identifies the source for logging, e.g. "github", "gitlab", "gitea".
+	Name() string
+
+	// ParseEvent decodes a webhook body into a normalized Event. eventType
+	// is the value of the forge's event-type header (e.g. X-GitHub-Event).
+	ParseEvent(eventType string, body []byte) (*Event, error)
+
+	// LoadOrCreateLabel returns the repo's label with the given name,
+	// creating it with the given color first if necessary. An empty color
+	// leaves the choice to the implementation's own default; color is
+	// ignored if the label already exists.
+	LoadOrCreateLabel(ctx context.Context, owner, repo, name, color string) (*Label, error)
+
+	// AddLabel applies a label to the pull request.
+	AddLabel(ctx context.Context, pr *PullRequest, name string) error
+
+	// RemoveLabel removes a label from the pull request. Implementations
+	// treat "label not present" as success.
+	RemoveLabel(ctx context.Context, pr *PullRequest, name string) error
+
+	// GetPullRequest fetches the current state of a pull request.
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error)
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"code.gitea.io/sdk/gitea"
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	gitlab "github.com/xanzy/go-gitlab"
+	"golang.org/x/oauth2"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultJWTTTL is how long the app-authentication JWT is valid for when
+// Config.JWTTTL isn't set. GitHub rejects anything over 10 minutes.
+const defaultJWTTTL = 5 * time.Minute
+
+// appJWTSource mints the short-lived JWT a GitHub App authenticates
+// itself with when exchanging it for an installation token.
+type appJWTSource struct {
+	appID int64
+	pem   *rsa.PrivateKey
+	ttl   time.Duration
+}
+
+func (t appJWTSource) Token() (*oauth2.Token, error) {
+	ttl := t.ttl
+	if ttl == 0 {
+		ttl = defaultJWTTTL
+	}
+	claim := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(t.appID, 10),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claim)
+	signedJwt, err := token.SignedString(t.pem)
+
+	return &oauth2.Token{
+		AccessToken: signedJwt,
+		TokenType:   "Bearer",
+	}, err
+}
+
+type InstallationTokenSource struct {
+	ctx            context.Context
+	installId      *int64
+	client         *github.Client
+	accessTokenUrl string
+}
+
+func (h *Handler) newInstallationTokenSource(ctx context.Context, installId *int64) (*InstallationTokenSource, error) {
+	httpClient := oauth2.NewClient(ctx, appJWTSource{appID: h.appID, pem: h.pem, ttl: h.jwtTTL})
+	ghClient, err := h.newGitHubClient(httpClient)
+	if err != nil {
+		return nil, err
+	}
+	install, _, err := ghClient.Apps.GetInstallation(ctx, *installId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstallationTokenSource{
+		ctx:            ctx,
+		installId:      installId,
+		client:         ghClient,
+		accessTokenUrl: install.GetAccessTokensURL(),
+	}, nil
+}
+
+func (t InstallationTokenSource) Token() (*oauth2.Token, error) {
+	req, err := t.client.NewRequest("POST", t.accessTokenUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &github.InstallationToken{}
+	resp, err := t.client.Do(t.ctx, req, token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.Errorf("Bad status code returned for access token url- %d %s", resp.StatusCode, respBody)
+	}
+
+	return &oauth2.Token{
+		AccessToken: *token.Token,
+		Expiry:      *token.ExpiresAt,
+		TokenType:   "token",
+	}, nil
+}
+
+func (h *Handler) initClientForInstallation(ctx context.Context, installId *int64) (*github.Client, error) {
+	source, err := h.newInstallationTokenSource(ctx, installId)
+	if err != nil {
+		return nil, err
+	}
+	return h.newGitHubClient(oauth2.NewClient(ctx, source))
+}
+
+// installationIDForRepo looks up the GitHub App installation covering
+// owner/repo, authenticating as the app itself rather than an
+// installation (there's no installation ID to use yet). Used by the
+// --warm admin command, which isn't triggered by a webhook event.
+func (h *Handler) installationIDForRepo(ctx context.Context, owner, repo string) (int64, error) {
+	httpClient := oauth2.NewClient(ctx, appJWTSource{appID: h.appID, pem: h.pem, ttl: h.jwtTTL})
+	ghClient, err := h.newGitHubClient(httpClient)
+	if err != nil {
+		return 0, err
+	}
+	install, _, err := ghClient.Apps.FindRepositoryInstallation(ctx, owner, repo)
+	if err != nil {
+		return 0, err
+	}
+	return install.GetID(), nil
+}
+
+// newGitHubClient builds a github.Client against api.github.com, or
+// against a GitHub Enterprise Server instance when Config.GitHubAPIURL is
+// set.
+func (h *Handler) newGitHubClient(httpClient *http.Client) (*github.Client, error) {
+	if h.githubAPIURL == "" {
+		return github.NewClient(httpClient), nil
+	}
+	uploadURL := h.githubUploadURL
+	if uploadURL == "" {
+		uploadURL = h.githubAPIURL
+	}
+	return github.NewEnterpriseClient(h.githubAPIURL, uploadURL, httpClient)
+}
+
+// verifyGitHubSignature checks the X-Hub-Signature-256 header GitHub sends
+// with every webhook delivery against an HMAC-SHA256 of the raw body,
+// computed with the shared webhook secret.
+func verifyGitHubSignature(secret []byte, signatureHeader string, body []byte) bool {
+	const prefix = "sha256="
+	if len(secret) == 0 || !strings.HasPrefix(signatureHeader, prefix) {
+		return false
+	}
+	expectedMAC, err := hex.DecodeString(strings.TrimPrefix(signatureHeader, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expectedMAC)
+}
+
+// newGitLabClient builds a GitLab API client from GITLAB_TOKEN (and
+// optionally GITLAB_API_URL for self-managed instances).
+func newGitLabClient() (*gitlab.Client, error) {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		return nil, errors.New("GITLAB_TOKEN not set")
+	}
+	opts := []gitlab.ClientOptionFunc{}
+	if baseURL := os.Getenv("GITLAB_API_URL"); baseURL != "" {
+		opts = append(opts, gitlab.WithBaseURL(baseURL))
+	}
+	return gitlab.NewClient(token, opts...)
+}
+
+// newGiteaClient builds a Gitea API client from GITEA_API_URL and
+// GITEA_TOKEN.
+func newGiteaClient() (*gitea.Client, error) {
+	baseURL := os.Getenv("GITEA_API_URL")
+	if baseURL == "" {
+		return nil, errors.New("GITEA_API_URL not set")
+	}
+	token := os.Getenv("GITEA_TOKEN")
+	if token == "" {
+		return nil, errors.New("GITEA_TOKEN not set")
+	}
+	return gitea.NewClient(baseURL, gitea.SetToken(token))
+}
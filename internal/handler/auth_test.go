@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func signBody(t *testing.T, secret, body []byte) string {
+	t.Helper()
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	secret := []byte("shhh")
+	body := []byte(`{"action":"opened"}`)
+
+	cases := []struct {
+		name      string
+		secret    []byte
+		signature string
+		body      []byte
+		want      bool
+	}{
+		{
+			name:      "valid signature",
+			secret:    secret,
+			signature: signBody(t, secret, body),
+			body:      body,
+			want:      true,
+		},
+		{
+			name:      "wrong secret",
+			secret:    secret,
+			signature: signBody(t, []byte("other"), body),
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "tampered body",
+			secret:    secret,
+			signature: signBody(t, secret, body),
+			body:      []byte(`{"action":"closed"}`),
+			want:      false,
+		},
+		{
+			name:      "missing header",
+			secret:    secret,
+			signature: "",
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "missing sha256 prefix",
+			secret:    secret,
+			signature: hex.EncodeToString([]byte("not-prefixed")),
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "non-hex signature",
+			secret:    secret,
+			signature: "sha256=not-hex",
+			body:      body,
+			want:      false,
+		},
+		{
+			name:      "no secret configured",
+			secret:    nil,
+			signature: signBody(t, secret, body),
+			body:      body,
+			want:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := verifyGitHubSignature(tc.secret, tc.signature, tc.body)
+			if got != tc.want {
+				t.Errorf("verifyGitHubSignature() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// DefaultAppID is whosecourt's own GitHub App ID on github.com. GHES
+// admins register their own app and override it with APP_ID.
+const DefaultAppID = 147975
+
+// LoadPemBytes reads the GitHub App's private key from PEM or PEMFILE and
+// parses it. Both entrypoints call this during startup.
+func LoadPemBytes() (*rsa.PrivateKey, error) {
+	var pemBytes []byte
+	if pemstring := os.Getenv("PEM"); pemstring != "" {
+		pemBytes = []byte(pemstring)
+	} else if pemfile := os.Getenv("PEMFILE"); pemfile != "" {
+		file, err := os.Open(pemfile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to find pemfile")
+		}
+		defer file.Close()
+		pemBytes, err = ioutil.ReadAll(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read pem bytes from file")
+		}
+	}
+	pem, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid PEM content")
+	}
+	return pem, nil
+}
+
+// LoadWebhookSecret reads the shared secret used to verify GitHub webhook
+// signatures from WEBHOOK_SECRET or WEBHOOK_SECRET_FILE. A missing secret
+// isn't an error- it just means every GitHub webhook will fail signature
+// verification until one is configured.
+func LoadWebhookSecret() ([]byte, error) {
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	if secretFile := os.Getenv("WEBHOOK_SECRET_FILE"); secretFile != "" {
+		file, err := os.Open(secretFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to find webhook secret file")
+		}
+		defer file.Close()
+		secretBytes, err := ioutil.ReadAll(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read webhook secret from file")
+		}
+		return []byte(strings.TrimSpace(string(secretBytes))), nil
+	}
+	return nil, nil
+}
+
+// LoadConfig builds a Config from the process environment: the GitHub
+// App's PEM and ID, the webhook secret, and- for GHES installs- the
+// Enterprise Server API/upload URLs. Both entrypoints call this so they
+// stay in sync as new settings are added.
+func LoadConfig(log *zap.SugaredLogger) (Config, error) {
+	pem, err := LoadPemBytes()
+	if err != nil {
+		return Config{}, errors.Wrap(err, "unable to load PEM")
+	}
+	webhookSecret, err := LoadWebhookSecret()
+	if err != nil {
+		return Config{}, errors.Wrap(err, "unable to load webhook secret")
+	}
+
+	appID := int64(DefaultAppID)
+	if v := os.Getenv("APP_ID"); v != "" {
+		appID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "invalid APP_ID")
+		}
+	}
+
+	var jwtTTL time.Duration
+	if v := os.Getenv("APP_JWT_TTL"); v != "" {
+		jwtTTL, err = time.ParseDuration(v)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "invalid APP_JWT_TTL")
+		}
+	}
+
+	return Config{
+		Log:             log,
+		Pem:             pem,
+		WebhookSecret:   webhookSecret,
+		AppID:           appID,
+		JWTTTL:          jwtTTL,
+		GitHubAPIURL:    os.Getenv("GITHUB_API_URL"),
+		GitHubUploadURL: os.Getenv("GITHUB_UPLOAD_URL"),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/PeladoCollado/reviewers_court/courtconfig"
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// courtConfigCacheSize bounds how many repositories' worth of parsed
+// .whosecourt.yml configs a warm Handler keeps around, the same way
+// labelcache.MemoryCache bounds its label entries.
+const courtConfigCacheSize = 128
+
+// configFetcher is implemented by gitsource.Sources that can fetch a raw
+// file out of the repository, e.g. GitHubSource via the contents API. A
+// Source that doesn't implement it (GitLab and Gitea, for now) always
+// gets courtconfig.Default().
+type configFetcher interface {
+	GetConfigFile(ctx context.Context, owner, repo, path string) (content []byte, ok bool, err error)
+}
+
+// loadCourtConfig returns owner/repo's parsed .whosecourt.yml, consulting
+// an in-process cache before hitting the forge's API- dispatchEvent calls
+// this on every webhook it doesn't ignore, so without it a busy PR (e.g.
+// one getting repeated review or comment events) would re-fetch and
+// re-parse the same file on every delivery.
+func (h *Handler) loadCourtConfig(ctx context.Context, source gitsource.Source, owner, repo string) *courtconfig.Config {
+	key := owner + "/" + repo
+	if cached, ok := h.courtConfigCache.Get(key); ok {
+		return cached.(*courtconfig.Config)
+	}
+
+	cfg := h.fetchCourtConfig(ctx, source, owner, repo)
+	h.courtConfigCache.Add(key, cfg)
+	return cfg
+}
+
+// fetchCourtConfig fetches and parses owner/repo's .whosecourt.yml,
+// falling back to courtconfig.Default() if the source can't fetch files,
+// the file doesn't exist, or it fails to parse.
+func (h *Handler) fetchCourtConfig(ctx context.Context, source gitsource.Source, owner, repo string) *courtconfig.Config {
+	fetcher, ok := source.(configFetcher)
+	if !ok {
+		return courtconfig.Default()
+	}
+
+	data, found, err := fetcher.GetConfigFile(ctx, owner, repo, courtconfig.ConfigPath)
+	if err != nil {
+		h.lg(ctx).Warnf("Unable to fetch %s- falling back to the default court workflow- %v", courtconfig.ConfigPath, err)
+		return courtconfig.Default()
+	}
+	if !found {
+		return courtconfig.Default()
+	}
+
+	cfg, err := courtconfig.Parse(data)
+	if err != nil {
+		h.lg(ctx).Warnf("Invalid %s- falling back to the default court workflow- %v", courtconfig.ConfigPath, err)
+		return courtconfig.Default()
+	}
+	return cfg
+}
+
+func newCourtConfigCache() (*lru.Cache, error) {
+	return lru.New(courtConfigCacheSize)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// fakeSource is a gitsource.Source test double. ParseEvent is supplied by
+// the test so handler tests can exercise dispatch logic without depending
+// on any one forge's webhook JSON shape- that's covered separately in
+// gitsource's own tests.
+type fakeSource struct {
+	name       string
+	parseEvent func(eventType string, body []byte) (*gitsource.Event, error)
+
+	labels map[string]*gitsource.Label
+
+	added   []string
+	removed []string
+
+	configFile    []byte
+	configFileOK  bool
+	configFileErr error
+	configCalls   int
+}
+
+func (f *fakeSource) Name() string { return f.name }
+
+func (f *fakeSource) ParseEvent(eventType string, body []byte) (*gitsource.Event, error) {
+	return f.parseEvent(eventType, body)
+}
+
+func (f *fakeSource) LoadOrCreateLabel(ctx context.Context, owner, repo, name, color string) (*gitsource.Label, error) {
+	if f.labels == nil {
+		f.labels = map[string]*gitsource.Label{}
+	}
+	if l, ok := f.labels[name]; ok {
+		return l, nil
+	}
+	l := &gitsource.Label{ID: int64(len(f.labels) + 1), Name: name}
+	f.labels[name] = l
+	return l, nil
+}
+
+func (f *fakeSource) AddLabel(ctx context.Context, pr *gitsource.PullRequest, name string) error {
+	f.added = append(f.added, name)
+	return nil
+}
+
+func (f *fakeSource) RemoveLabel(ctx context.Context, pr *gitsource.PullRequest, name string) error {
+	f.removed = append(f.removed, name)
+	return nil
+}
+
+func (f *fakeSource) GetPullRequest(ctx context.Context, owner, repo string, number int) (*gitsource.PullRequest, error) {
+	return &gitsource.PullRequest{Owner: owner, Repo: repo, Number: number}, nil
+}
+
+// GetConfigFile makes fakeSource satisfy configFetcher, so dispatchEvent's
+// loadCourtConfig calls through to it like GitHubSource does.
+func (f *fakeSource) GetConfigFile(ctx context.Context, owner, repo, path string) ([]byte, bool, error) {
+	f.configCalls++
+	return f.configFile, f.configFileOK, f.configFileErr
+}
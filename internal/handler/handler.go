@@ -0,0 +1,374 @@
+// Package handler holds the forge-agnostic core of whosecourt: given a
+// webhook request, figure out which git source it came from, what it
+// means, and move the pull request's court label accordingly. It is a
+// plain http.Handler so it can be driven by either the Lambda adapter in
+// the repo-root package main (the historical entrypoint) or the
+// standalone server in cmd/server.
+package handler
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/PeladoCollado/reviewers_court/courtconfig"
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+	"github.com/PeladoCollado/reviewers_court/labelcache"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Config carries everything the handler needs that isn't discovered at
+// request time.
+type Config struct {
+	Log           *zap.SugaredLogger
+	Pem           *rsa.PrivateKey
+	WebhookSecret []byte
+
+	// AppID is the GitHub App ID used to sign installation-token
+	// requests. GHES admins register their own apps, so this- and the
+	// API URLs below- are configuration rather than a shared constant.
+	AppID int64
+	// JWTTTL overrides how long the app-authentication JWT is valid for.
+	// Defaults to 5 minutes when zero.
+	JWTTTL time.Duration
+
+	// GitHubAPIURL and GitHubUploadURL point the GitHub client at a
+	// GitHub Enterprise Server instance instead of api.github.com. Both
+	// empty means github.com.
+	GitHubAPIURL    string
+	GitHubUploadURL string
+
+	// LabelCache holds each repository's court labels across requests.
+	// Defaults to a small in-memory LRU if nil.
+	LabelCache labelcache.Cache
+}
+
+// Handler is the shared whosecourt webhook handler. It is safe for
+// concurrent use; labelCache is shared across requests to save refetching
+// or recreating labels on every event.
+type Handler struct {
+	log             *zap.SugaredLogger
+	pem             *rsa.PrivateKey
+	webhookSecret   []byte
+	appID           int64
+	jwtTTL          time.Duration
+	githubAPIURL    string
+	githubUploadURL string
+
+	courtCommentRegex *regexp.Regexp
+	labelCache        labelcache.Cache
+	courtConfigCache  *lru.Cache
+
+	// resolveSource is set to h.sourceForRequest by New. It's a field
+	// rather than a direct call so tests can substitute a fake
+	// gitsource.Source without authenticating against a real forge.
+	resolveSource func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error)
+}
+
+// New builds a Handler from its Config, compiling the court-comment regex
+// once up front.
+func New(cfg Config) (*Handler, error) {
+	re, err := regexp.Compile("<!-- ([\\w_]+) -->")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't compile the court comment regex")
+	}
+
+	cache := cfg.LabelCache
+	if cache == nil {
+		cache, err = labelcache.NewMemoryCache(128)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to build default label cache")
+		}
+	}
+
+	courtConfigCache, err := newCourtConfigCache()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build court config cache")
+	}
+
+	h := &Handler{
+		log:               cfg.Log,
+		pem:               cfg.Pem,
+		webhookSecret:     cfg.WebhookSecret,
+		appID:             cfg.AppID,
+		jwtTTL:            cfg.JWTTTL,
+		githubAPIURL:      cfg.GitHubAPIURL,
+		githubUploadURL:   cfg.GitHubUploadURL,
+		courtCommentRegex: re,
+		labelCache:        cache,
+		courtConfigCache:  courtConfigCache,
+	}
+	h.resolveSource = h.sourceForRequest
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	headers := normalizeHeaders(r.Header)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.log.Errorf("Unable to read request body- %v", err)
+		http.Error(w, "unable to read request body", http.StatusBadRequest)
+		return
+	}
+
+	reqLog := h.log
+	if delivery := headers["x-github-delivery"]; delivery != "" {
+		reqLog = h.log.With("github_delivery", delivery)
+	}
+	ctx := withLogger(r.Context(), reqLog)
+
+	if _, ok := headers["x-github-event"]; ok {
+		if !verifyGitHubSignature(h.webhookSecret, headers["x-hub-signature-256"], body) {
+			reqLog.Warn("Rejecting GitHub webhook with missing or invalid signature")
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	source, eventType, err := h.resolveSource(ctx, headers, body)
+	if err != nil {
+		reqLog.Errorf("Unable to determine git source for request- %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if source == nil {
+		// no recognized event header- nothing to do
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event, err := source.ParseEvent(eventType, body)
+	if err != nil {
+		reqLog.Errorf("Unable to parse %s event- %v", source.Name(), err)
+		http.Error(w, errors.Wrap(err, "invalid JSON received").Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatchEvent(ctx, source, event); err != nil {
+		reqLog.Errorf("Error handling request %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// sourceForRequest inspects the inbound webhook headers and builds the
+// gitsource.Source that should handle the request, authenticating against
+// the appropriate forge along the way.
+func (h *Handler) sourceForRequest(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+	if githubEvent, ok := headers["x-github-event"]; ok {
+		installID, err := installationIDFromPayload(body)
+		if err != nil {
+			return nil, "", err
+		}
+		client, err := h.initClientForInstallation(ctx, installID)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "unable to connect to github")
+		}
+		return gitsource.NewGitHubSource(client), githubEvent, nil
+	}
+	if gitlabEvent, ok := headers["x-gitlab-event"]; ok {
+		client, err := newGitLabClient()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "unable to connect to gitlab")
+		}
+		return gitsource.NewGitLabSource(client), gitlabEvent, nil
+	}
+	if giteaEvent, ok := headers["x-gitea-event"]; ok {
+		client, err := newGiteaClient()
+		if err != nil {
+			return nil, "", errors.Wrap(err, "unable to connect to gitea")
+		}
+		return gitsource.NewGiteaSource(client), giteaEvent, nil
+	}
+	return nil, "", nil
+}
+
+func (h *Handler) dispatchEvent(ctx context.Context, source gitsource.Source, event *gitsource.Event) error {
+	if event.Action == gitsource.ActionPing {
+		h.lg(ctx).Infof("%s ping received", source.Name())
+		return nil
+	}
+	if event.PullRequest == nil {
+		return nil
+	}
+	if event.Action == gitsource.ActionIgnored {
+		return nil
+	}
+
+	cfg := h.loadCourtConfig(ctx, source, event.PullRequest.Owner, event.PullRequest.Repo)
+
+	switch event.Action {
+	case gitsource.ActionReviewRequested:
+		if _, err := h.loadLabels(ctx, source, cfg, event.PullRequest.Owner, event.PullRequest.Repo); err != nil {
+			return errors.Wrap(err, "unable to load labels for repository")
+		}
+		return h.changeCourt(ctx, source, cfg, cfg.CourtForAction(string(gitsource.ActionReviewRequested)), event.PullRequest)
+	case gitsource.ActionUnlabeled:
+		labels, err := h.loadLabels(ctx, source, cfg, event.PullRequest.Owner, event.PullRequest.Repo)
+		if err != nil {
+			return errors.Wrap(err, "unable to load labels for repository")
+		}
+		courtLabelPresent := false
+		for _, id := range event.PullRequest.LabelIDs {
+			for _, label := range labels {
+				if label.ID == id {
+					courtLabelPresent = true
+					break
+				}
+			}
+		}
+		if courtLabelPresent {
+			h.lg(ctx).Info("PR was manually labeled- no action taken")
+			return nil
+		}
+		court := cfg.AuthorCourt
+		if event.PullRequest.SenderID == event.PullRequest.AuthorID {
+			court = cfg.ReviewerCourt
+		}
+		return h.changeCourt(ctx, source, cfg, court, event.PullRequest)
+	case gitsource.ActionReviewed:
+		if _, err := h.loadLabels(ctx, source, cfg, event.PullRequest.Owner, event.PullRequest.Repo); err != nil {
+			return errors.Wrap(err, "unable to load labels for repository")
+		}
+		return h.changeCourt(ctx, source, cfg, cfg.CourtForAction(string(gitsource.ActionReviewed)), event.PullRequest)
+	case gitsource.ActionCourtComment:
+		if _, err := h.loadLabels(ctx, source, cfg, event.PullRequest.Owner, event.PullRequest.Repo); err != nil {
+			return errors.Wrap(err, "unable to load labels for repository")
+		}
+		if !h.courtCommentRegex.MatchString(event.Comment) {
+			return nil
+		}
+		matches := h.courtCommentRegex.FindStringSubmatch(event.Comment)
+		if len(matches) <= 1 {
+			return nil
+		}
+		court, ok := cfg.CourtForComment(matches[1])
+		if !ok {
+			h.lg(ctx).Infof("Comment trigger %q isn't defined in %s- ignoring", matches[1], courtconfig.ConfigPath)
+			return nil
+		}
+		return h.changeCourt(ctx, source, cfg, court, event.PullRequest)
+	}
+	return nil
+}
+
+// loadLabels returns owner/repo's court labels for cfg's workflow,
+// consulting the label cache before hitting the forge's API. On a cache
+// miss, labels are fetched (or created, if missing) and written back to
+// the cache.
+func (h *Handler) loadLabels(ctx context.Context, source gitsource.Source, cfg *courtconfig.Config, owner, repo string) (map[string]*gitsource.Label, error) {
+	names := cfg.CourtNames()
+
+	if cached, ok, err := h.labelCache.Get(ctx, owner, repo); err != nil {
+		h.lg(ctx).Warnf("Label cache read failed- %v", err)
+	} else if ok && len(cached) == len(names) {
+		return cached, nil
+	}
+
+	labels := make(map[string]*gitsource.Label, len(names))
+	for _, name := range names {
+		label, err := source.LoadOrCreateLabel(ctx, owner, repo, name, cfg.ColorFor(name))
+		if err != nil {
+			return nil, err
+		}
+		labels[label.Name] = label
+	}
+	h.lg(ctx).Info("Found labels %v", labels)
+
+	if err := h.labelCache.Put(ctx, owner, repo, labels); err != nil {
+		h.lg(ctx).Warnf("Label cache write failed- %v", err)
+	}
+	return labels, nil
+}
+
+// WarmGitHubRepo pre-populates the label cache for owner/repo on
+// github.com (or the configured GitHub Enterprise Server instance),
+// fetching or creating whosecourt's labels ahead of the first webhook
+// delivery. Intended for the --warm admin command.
+func (h *Handler) WarmGitHubRepo(ctx context.Context, owner, repo string) error {
+	installID, err := h.installationIDForRepo(ctx, owner, repo)
+	if err != nil {
+		return errors.Wrap(err, "unable to find installation")
+	}
+	client, err := h.initClientForInstallation(ctx, &installID)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to github")
+	}
+	source := gitsource.NewGitHubSource(client)
+	cfg := h.loadCourtConfig(ctx, source, owner, repo)
+	_, err = h.loadLabels(ctx, source, cfg, owner, repo)
+	return err
+}
+
+// changeCourt moves pr to court, removing every other court in cfg's
+// workflow so exactly one is active at a time.
+func (h *Handler) changeCourt(ctx context.Context, source gitsource.Source, cfg *courtconfig.Config, court string, pr *gitsource.PullRequest) error {
+	h.lg(ctx).Infof("Changing %d's court to %s", pr.Number, court)
+	for _, name := range cfg.CourtNames() {
+		if name == court {
+			continue
+		}
+		if err := source.RemoveLabel(ctx, pr, name); err != nil {
+			return err
+		}
+	}
+	return source.AddLabel(ctx, pr, court)
+}
+
+// installationIDFromPayload pulls just the GitHub App installation ID out
+// of a raw webhook body, without committing to any one event type's shape.
+func installationIDFromPayload(body []byte) (*int64, error) {
+	payload := struct {
+		Installation struct {
+			ID int64 `json:"id"`
+		} `json:"installation"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid JSON received")
+	}
+	return &payload.Installation.ID, nil
+}
+
+// normalizeHeaders collapses an http.Header into the single-value,
+// lowercase-keyed map the rest of the handler expects- the same shape
+// API Gateway hands Lambda.
+func normalizeHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k, v := range h {
+		if len(v) > 0 {
+			headers[strings.ToLower(k)] = v[0]
+		}
+	}
+	return headers
+}
+
+type loggerCtxKey struct{}
+
+// withLogger attaches a request-scoped logger (e.g. one annotated with a
+// delivery ID) to ctx.
+func withLogger(ctx context.Context, l *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// lg returns the request-scoped logger from ctx, falling back to the
+// handler's own logger if none was attached.
+func (h *Handler) lg(ctx context.Context) *zap.SugaredLogger {
+	if l, ok := ctx.Value(loggerCtxKey{}).(*zap.SugaredLogger); ok {
+		return l
+	}
+	return h.log
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	h, err := New(Config{
+		Log:           zap.NewNop().Sugar(),
+		WebhookSecret: []byte("shhh"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return h
+}
+
+func postRequest(headers map[string]string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestServeHTTPIgnoresNonPostMethod(t *testing.T) {
+	h := newTestHandler(t)
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		t.Fatal("resolveSource should not be called for a non-POST request")
+		return nil, "", nil
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServeHTTPRejectsInvalidGitHubSignature(t *testing.T) {
+	h := newTestHandler(t)
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		t.Fatal("resolveSource should not be called once the signature check fails")
+		return nil, "", nil
+	}
+
+	req := postRequest(map[string]string{
+		"X-GitHub-Event":      "ping",
+		"X-Hub-Signature-256": "sha256=deadbeef",
+	}, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestServeHTTPAcceptsValidGitHubSignatureAndHandlesPing(t *testing.T) {
+	h := newTestHandler(t)
+	signature := signBody(t, h.webhookSecret, []byte(`{}`))
+	source := &fakeSource{
+		name: "github",
+		parseEvent: func(eventType string, body []byte) (*gitsource.Event, error) {
+			if eventType != "ping" {
+				t.Errorf("eventType = %q, want ping", eventType)
+			}
+			return &gitsource.Event{Action: gitsource.ActionPing}, nil
+		},
+	}
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		if headers["x-github-event"] != "ping" {
+			t.Errorf("headers[x-github-event] = %q, want ping", headers["x-github-event"])
+		}
+		return source, "ping", nil
+	}
+
+	req := postRequest(map[string]string{
+		"X-GitHub-Event":      "ping",
+		"X-Hub-Signature-256": signature,
+	}, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(source.added) != 0 || len(source.removed) != 0 {
+		t.Errorf("ping event should not touch labels, got added=%v removed=%v", source.added, source.removed)
+	}
+}
+
+func TestServeHTTPNoRecognizedEventHeader(t *testing.T) {
+	h := newTestHandler(t)
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		return nil, "", nil
+	}
+
+	req := postRequest(nil, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestServeHTTPResolveSourceError(t *testing.T) {
+	h := newTestHandler(t)
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		return nil, "", errors.New("unable to connect")
+	}
+
+	req := postRequest(map[string]string{"X-Gitlab-Event": "Merge Request Hook"}, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServeHTTPParseEventError(t *testing.T) {
+	h := newTestHandler(t)
+	source := &fakeSource{
+		name: "gitlab",
+		parseEvent: func(eventType string, body []byte) (*gitsource.Event, error) {
+			return nil, errors.New("invalid JSON received")
+		},
+	}
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		return source, "Merge Request Hook", nil
+	}
+
+	req := postRequest(map[string]string{"X-Gitlab-Event": "Merge Request Hook"}, `not json`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServeHTTPUnlabeledMovesToAuthorCourt(t *testing.T) {
+	h := newTestHandler(t)
+	pr := &gitsource.PullRequest{Owner: "acme", Repo: "widgets", Number: 5, AuthorID: 42, SenderID: 99}
+	source := &fakeSource{
+		name: "gitlab",
+		parseEvent: func(eventType string, body []byte) (*gitsource.Event, error) {
+			return &gitsource.Event{Action: gitsource.ActionUnlabeled, PullRequest: pr}, nil
+		},
+	}
+	h.resolveSource = func(ctx context.Context, headers map[string]string, body []byte) (gitsource.Source, string, error) {
+		return source, "Merge Request Hook", nil
+	}
+
+	req := postRequest(map[string]string{"X-Gitlab-Event": "Merge Request Hook"}, `{}`)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(source.added) != 1 || source.added[0] != "authors_court" {
+		t.Errorf("added = %v, want [authors_court] (sender isn't the author)", source.added)
+	}
+}
+
+func TestDispatchEventIgnoredSkipsCourtConfigFetch(t *testing.T) {
+	h := newTestHandler(t)
+	source := &fakeSource{name: "github"}
+	pr := &gitsource.PullRequest{Owner: "acme", Repo: "widgets", Number: 5}
+
+	if err := h.dispatchEvent(context.Background(), source, &gitsource.Event{Action: gitsource.ActionIgnored, PullRequest: pr}); err != nil {
+		t.Fatalf("dispatchEvent() error = %v", err)
+	}
+	if source.configCalls != 0 {
+		t.Errorf("GetConfigFile was called %d times for an ignored event, want 0", source.configCalls)
+	}
+}
+
+func TestDispatchEventReviewRequestedFetchesCourtConfig(t *testing.T) {
+	h := newTestHandler(t)
+	source := &fakeSource{name: "github"}
+	pr := &gitsource.PullRequest{Owner: "acme", Repo: "widgets", Number: 5}
+
+	if err := h.dispatchEvent(context.Background(), source, &gitsource.Event{Action: gitsource.ActionReviewRequested, PullRequest: pr}); err != nil {
+		t.Fatalf("dispatchEvent() error = %v", err)
+	}
+	if source.configCalls != 1 {
+		t.Errorf("GetConfigFile was called %d times, want 1", source.configCalls)
+	}
+	if len(source.added) != 1 || source.added[0] != "reviewers_court" {
+		t.Errorf("added = %v, want [reviewers_court]", source.added)
+	}
+
+	// A second event against the same repo should reuse the cached config.
+	if err := h.dispatchEvent(context.Background(), source, &gitsource.Event{Action: gitsource.ActionReviewRequested, PullRequest: pr}); err != nil {
+		t.Fatalf("dispatchEvent() error = %v", err)
+	}
+	if source.configCalls != 1 {
+		t.Errorf("GetConfigFile was called %d times across two events for the same repo, want 1 (cached)", source.configCalls)
+	}
+}
+
+func TestDispatchEventCourtComment(t *testing.T) {
+	h := newTestHandler(t)
+	source := &fakeSource{name: "github"}
+	pr := &gitsource.PullRequest{Owner: "acme", Repo: "widgets", Number: 5}
+
+	event := &gitsource.Event{Action: gitsource.ActionCourtComment, PullRequest: pr, Comment: "<!-- authors_court -->"}
+	if err := h.dispatchEvent(context.Background(), source, event); err != nil {
+		t.Fatalf("dispatchEvent() error = %v", err)
+	}
+	if len(source.added) != 1 || source.added[0] != "authors_court" {
+		t.Errorf("added = %v, want [authors_court]", source.added)
+	}
+}
+
+func TestSourceForRequestNoRecognizedHeader(t *testing.T) {
+	h := newTestHandler(t)
+	source, eventType, err := h.sourceForRequest(context.Background(), map[string]string{}, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("sourceForRequest() error = %v", err)
+	}
+	if source != nil || eventType != "" {
+		t.Errorf("sourceForRequest() = (%v, %q), want (nil, \"\") with no recognized event header", source, eventType)
+	}
+}
+
+func TestSourceForRequestGitLabMissingToken(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	h := newTestHandler(t)
+	_, _, err := h.sourceForRequest(context.Background(), map[string]string{"x-gitlab-event": "Merge Request Hook"}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("sourceForRequest() with no GITLAB_TOKEN returned nil error")
+	}
+}
+
+func TestSourceForRequestGiteaMissingConfig(t *testing.T) {
+	t.Setenv("GITEA_API_URL", "")
+	t.Setenv("GITEA_TOKEN", "")
+	h := newTestHandler(t)
+	_, _, err := h.sourceForRequest(context.Background(), map[string]string{"x-gitea-event": "pull_request"}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("sourceForRequest() with no GITEA_API_URL/GITEA_TOKEN returned nil error")
+	}
+}
+
+func TestDispatchEventCourtCommentWithoutTrigger(t *testing.T) {
+	h := newTestHandler(t)
+	source := &fakeSource{name: "github"}
+	pr := &gitsource.PullRequest{Owner: "acme", Repo: "widgets", Number: 5}
+
+	event := &gitsource.Event{Action: gitsource.ActionCourtComment, PullRequest: pr, Comment: "just chatting"}
+	if err := h.dispatchEvent(context.Background(), source, event); err != nil {
+		t.Fatalf("dispatchEvent() error = %v", err)
+	}
+	if len(source.added) != 0 {
+		t.Errorf("added = %v, want none for a comment with no court trigger", source.added)
+	}
+}
@@ -0,0 +1,93 @@
+package labelcache
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// DynamoDBCache persists court labels per repository in a DynamoDB table,
+// so a cold Lambda start doesn't have to re-discover or re-create labels.
+// The table needs a string partition key named "repo"; if ttl is set when
+// the cache is built, enable TTL on the table's "expires_at" attribute to
+// have DynamoDB reap expired entries.
+type DynamoDBCache struct {
+	client    *dynamodb.DynamoDB
+	tableName string
+	ttl       time.Duration
+}
+
+type dynamoItem struct {
+	Repo      string                     `dynamodbav:"repo"`
+	Labels    map[string]gitsource.Label `dynamodbav:"labels"`
+	ExpiresAt int64                      `dynamodbav:"expires_at,omitempty"`
+}
+
+// NewDynamoDBCache builds a DynamoDBCache against tableName using the
+// default AWS credential chain. A zero ttl means entries never expire.
+func NewDynamoDBCache(tableName string, ttl time.Duration) (*DynamoDBCache, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create aws session")
+	}
+	return &DynamoDBCache{
+		client:    dynamodb.New(sess),
+		tableName: tableName,
+		ttl:       ttl,
+	}, nil
+}
+
+func (d *DynamoDBCache) Get(ctx context.Context, owner, repo string) (map[string]*gitsource.Label, bool, error) {
+	out, err := d.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"repo": {S: aws.String(key(owner, repo))},
+		},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(out.Item) == 0 {
+		return nil, false, nil
+	}
+
+	var item dynamoItem
+	if err := dynamodbattribute.UnmarshalMap(out.Item, &item); err != nil {
+		return nil, false, err
+	}
+
+	labels := make(map[string]*gitsource.Label, len(item.Labels))
+	for name, l := range item.Labels {
+		l := l
+		labels[name] = &l
+	}
+	return labels, true, nil
+}
+
+func (d *DynamoDBCache) Put(ctx context.Context, owner, repo string, labels map[string]*gitsource.Label) error {
+	flat := make(map[string]gitsource.Label, len(labels))
+	for name, l := range labels {
+		flat[name] = *l
+	}
+	item := dynamoItem{Repo: key(owner, repo), Labels: flat}
+	if d.ttl > 0 {
+		item.ExpiresAt = time.Now().Add(d.ttl).Unix()
+	}
+
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	_, err = d.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item:      av,
+	})
+	return err
+}
@@ -0,0 +1,69 @@
+package labelcache
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// TestDynamoItemRoundTrip exercises the marshal/unmarshal shape Get and Put
+// rely on, without needing a live DynamoDB table.
+func TestDynamoItemRoundTrip(t *testing.T) {
+	item := dynamoItem{
+		Repo: key("owner", "repo"),
+		Labels: map[string]gitsource.Label{
+			"reviewers_court": {ID: 1, Name: "reviewers_court"},
+			"authors_court":   {ID: 2, Name: "authors_court"},
+		},
+		ExpiresAt: 1700000000,
+	}
+
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+
+	var got dynamoItem
+	if err := dynamodbattribute.UnmarshalMap(av, &got); err != nil {
+		t.Fatalf("UnmarshalMap() error = %v", err)
+	}
+
+	if got.Repo != item.Repo {
+		t.Errorf("Repo = %q, want %q", got.Repo, item.Repo)
+	}
+	if got.ExpiresAt != item.ExpiresAt {
+		t.Errorf("ExpiresAt = %d, want %d", got.ExpiresAt, item.ExpiresAt)
+	}
+	if len(got.Labels) != len(item.Labels) {
+		t.Fatalf("Labels = %+v, want %+v", got.Labels, item.Labels)
+	}
+	for name, l := range item.Labels {
+		if got.Labels[name] != l {
+			t.Errorf("Labels[%q] = %+v, want %+v", name, got.Labels[name], l)
+		}
+	}
+}
+
+// TestDynamoItemRoundTripNoTTL confirms the omitempty tag keeps untouched
+// entries from getting a bogus expiry once they round-trip.
+func TestDynamoItemRoundTripNoTTL(t *testing.T) {
+	item := dynamoItem{
+		Repo:   key("owner", "repo"),
+		Labels: map[string]gitsource.Label{"reviewers_court": {ID: 1, Name: "reviewers_court"}},
+	}
+
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		t.Fatalf("MarshalMap() error = %v", err)
+	}
+
+	var got dynamoItem
+	if err := dynamodbattribute.UnmarshalMap(av, &got); err != nil {
+		t.Fatalf("UnmarshalMap() error = %v", err)
+	}
+	if got.ExpiresAt != 0 {
+		t.Errorf("ExpiresAt = %d, want 0 when ttl is unset", got.ExpiresAt)
+	}
+}
@@ -0,0 +1,63 @@
+// Package labelcache stores, per repository, the court labels whosecourt
+// has already found or created- so a warm Lambda doesn't leak label IDs
+// across repos, and a cold one doesn't have to re-hit the GitHub API on
+// every invocation.
+package labelcache
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// defaultMemorySize is how many repositories' worth of labels a
+// MemoryCache holds before evicting the least recently used.
+const defaultMemorySize = 128
+
+// Cache stores each repository's court labels, keyed by label name.
+type Cache interface {
+	// Get returns the cached labels for owner/repo, or ok=false on a
+	// cache miss.
+	Get(ctx context.Context, owner, repo string) (labels map[string]*gitsource.Label, ok bool, err error)
+
+	// Put stores the labels known for owner/repo, overwriting any
+	// previous entry.
+	Put(ctx context.Context, owner, repo string, labels map[string]*gitsource.Label) error
+}
+
+func key(owner, repo string) string {
+	return owner + "/" + repo
+}
+
+// NewFromEnv picks the cache implementation a Lambda deployment should
+// use: DynamoDB when DYNAMODB_TABLE_NAME is set, otherwise an in-memory
+// LRU (sized by LABEL_CACHE_SIZE, default 128- fine for a single warm
+// container, but lost on every cold start).
+func NewFromEnv() (Cache, error) {
+	if tableName := os.Getenv("DYNAMODB_TABLE_NAME"); tableName != "" {
+		var ttl time.Duration
+		if v := os.Getenv("DYNAMODB_LABEL_TTL"); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid DYNAMODB_LABEL_TTL")
+			}
+			ttl = parsed
+		}
+		return NewDynamoDBCache(tableName, ttl)
+	}
+	return NewMemoryCache(memorySizeFromEnv())
+}
+
+func memorySizeFromEnv() int {
+	if v := os.Getenv("LABEL_CACHE_SIZE"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultMemorySize
+}
@@ -0,0 +1,45 @@
+package labelcache
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+// MemoryCache is an in-process LRU, appropriate for the standalone server
+// where one long-lived process sees many repositories over its lifetime.
+// It does not survive a restart.
+type MemoryCache struct {
+	mu    sync.Mutex
+	cache *lru.Cache
+}
+
+// NewMemoryCache builds a MemoryCache holding up to size repositories'
+// worth of labels.
+func NewMemoryCache(size int) (*MemoryCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryCache{cache: cache}, nil
+}
+
+func (m *MemoryCache) Get(ctx context.Context, owner, repo string) (map[string]*gitsource.Label, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.cache.Get(key(owner, repo))
+	if !ok {
+		return nil, false, nil
+	}
+	return v.(map[string]*gitsource.Label), true, nil
+}
+
+func (m *MemoryCache) Put(ctx context.Context, owner, repo string, labels map[string]*gitsource.Label) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache.Add(key(owner, repo), labels)
+	return nil
+}
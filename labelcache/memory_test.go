@@ -0,0 +1,81 @@
+package labelcache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PeladoCollado/reviewers_court/gitsource"
+)
+
+func TestMemoryCacheGetPut(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewMemoryCache(2)
+	if err != nil {
+		t.Fatalf("NewMemoryCache() error = %v", err)
+	}
+
+	if _, ok, err := cache.Get(ctx, "owner", "repo"); err != nil || ok {
+		t.Fatalf("Get() on empty cache = (ok=%v, err=%v), want (false, nil)", ok, err)
+	}
+
+	labels := map[string]*gitsource.Label{
+		"reviewers_court": {ID: 1, Name: "reviewers_court"},
+	}
+	if err := cache.Put(ctx, "owner", "repo", labels); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	got, ok, err := cache.Get(ctx, "owner", "repo")
+	if err != nil || !ok {
+		t.Fatalf("Get() = (ok=%v, err=%v), want (true, nil)", ok, err)
+	}
+	if got["reviewers_court"].ID != 1 {
+		t.Errorf("Get() returned %+v, want label ID 1", got["reviewers_court"])
+	}
+}
+
+func TestMemoryCacheKeyedByRepo(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewMemoryCache(2)
+	if err != nil {
+		t.Fatalf("NewMemoryCache() error = %v", err)
+	}
+
+	a := map[string]*gitsource.Label{"reviewers_court": {ID: 1, Name: "reviewers_court"}}
+	b := map[string]*gitsource.Label{"reviewers_court": {ID: 2, Name: "reviewers_court"}}
+	if err := cache.Put(ctx, "owner", "repo-a", a); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := cache.Put(ctx, "owner", "repo-b", b); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	gotA, _, _ := cache.Get(ctx, "owner", "repo-a")
+	gotB, _, _ := cache.Get(ctx, "owner", "repo-b")
+	if gotA["reviewers_court"].ID != 1 || gotB["reviewers_court"].ID != 2 {
+		t.Errorf("entries leaked across repos: repo-a=%+v repo-b=%+v", gotA, gotB)
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	cache, err := NewMemoryCache(1)
+	if err != nil {
+		t.Fatalf("NewMemoryCache() error = %v", err)
+	}
+
+	labels := map[string]*gitsource.Label{"reviewers_court": {ID: 1, Name: "reviewers_court"}}
+	if err := cache.Put(ctx, "owner", "repo-a", labels); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := cache.Put(ctx, "owner", "repo-b", labels); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, ok, _ := cache.Get(ctx, "owner", "repo-a"); ok {
+		t.Error("repo-a should have been evicted once the cache exceeded its size")
+	}
+	if _, ok, _ := cache.Get(ctx, "owner", "repo-b"); !ok {
+		t.Error("repo-b should still be cached")
+	}
+}